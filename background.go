@@ -0,0 +1,322 @@
+package letterbox
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// defaultEdgeStripWidth is the default width, in source pixels, of the
+// strip sampled along each edge parallel to the letterbox bars. See
+// WithEdgeStripWidth.
+const defaultEdgeStripWidth = 8
+
+// WithEdgeStripWidth changes the width, in source pixels, of the strip
+// sampled along each edge for the "edge-average" and "dominant"
+// background modes. Defaults to 8.
+func WithEdgeStripWidth(n int) Option {
+	return func(p *Processor) error {
+		p.edgeStripWidth = n
+		return nil
+	}
+}
+
+// background returns the image drawn into db before src is composited
+// on top, according to p.backgroundMode.
+func (p *Processor) background(src image.Image, sb, db image.Rectangle) image.Image {
+	switch p.backgroundMode {
+	case "white":
+		return &image.Uniform{color.White}
+	case "edge-average":
+		return &image.Uniform{p.edgeAverageColor(src, sb, db)}
+	case "dominant":
+		return &image.Uniform{p.dominantColor(src, sb, db)}
+	case "blur":
+		return blurredBackground(src, db)
+	case "black":
+		return &image.Uniform{color.Black}
+	default:
+		return &image.Uniform{withColor(p.white)}
+	}
+}
+
+// edgeStrips returns the regions of sb to sample for the edges that will
+// be covered by letterbox bars once src is centered in db.
+func (p *Processor) edgeStrips(sb, db image.Rectangle) []image.Rectangle {
+	w := p.edgeStripWidth
+	var strips []image.Rectangle
+
+	if db.Dy() > sb.Dy() {
+		// bars above and below, sample the top and bottom of src
+		strips = append(strips,
+			image.Rect(sb.Min.X, sb.Min.Y, sb.Max.X, sb.Min.Y+w),
+			image.Rect(sb.Min.X, sb.Max.Y-w, sb.Max.X, sb.Max.Y))
+	}
+
+	if db.Dx() > sb.Dx() {
+		// bars left and right, sample the left and right of src
+		strips = append(strips,
+			image.Rect(sb.Min.X, sb.Min.Y, sb.Min.X+w, sb.Max.Y),
+			image.Rect(sb.Max.X-w, sb.Min.Y, sb.Max.X, sb.Max.Y))
+	}
+
+	if len(strips) == 0 {
+		strips = append(strips, sb)
+	}
+
+	return strips
+}
+
+// edgeAverageColor returns the average color, in linear RGB, of the
+// strips of src that border the letterbox bars.
+func (p *Processor) edgeAverageColor(src image.Image, sb, db image.Rectangle) color.Color {
+	var r, g, b, n float64
+
+	for _, strip := range p.edgeStrips(sb, db) {
+		strip = strip.Intersect(src.Bounds())
+		for y := strip.Min.Y; y < strip.Max.Y; y++ {
+			for x := strip.Min.X; x < strip.Max.X; x++ {
+				cr, cg, cb, _ := src.At(x, y).RGBA()
+				r += srgbToLinear(float64(cr) / 0xffff)
+				g += srgbToLinear(float64(cg) / 0xffff)
+				b += srgbToLinear(float64(cb) / 0xffff)
+				n++
+			}
+		}
+	}
+
+	if n == 0 {
+		return color.Black
+	}
+
+	return color.NRGBA{
+		R: linearToSRGB8(r / n),
+		G: linearToSRGB8(g / n),
+		B: linearToSRGB8(b / n),
+		A: 0xff,
+	}
+}
+
+// kmeans clusters samples into k groups over iterations rounds,
+// returning the final cluster centers and each sample's assignment.
+func kmeans(samples [][3]float64, k, iterations int) (centers [][3]float64, assignments []int) {
+	centers = make([][3]float64, k)
+	for i := range centers {
+		centers[i] = samples[(i*len(samples))/k]
+	}
+
+	assignments = make([]int, len(samples))
+	for iter := 0; iter < iterations; iter++ {
+		for i, s := range samples {
+			best, bestDist := 0, math.Inf(1)
+			for c, center := range centers {
+				d := sqDist(s, center)
+				if d < bestDist {
+					best, bestDist = c, d
+				}
+			}
+			assignments[i] = best
+		}
+
+		sums := make([][3]float64, k)
+		counts := make([]int, k)
+		for i, s := range samples {
+			c := assignments[i]
+			sums[c][0] += s[0]
+			sums[c][1] += s[1]
+			sums[c][2] += s[2]
+			counts[c]++
+		}
+		for c := range centers {
+			if counts[c] == 0 {
+				continue
+			}
+			centers[c] = [3]float64{
+				sums[c][0] / float64(counts[c]),
+				sums[c][1] / float64(counts[c]),
+				sums[c][2] / float64(counts[c]),
+			}
+		}
+	}
+
+	return centers, assignments
+}
+
+// dominantColor runs a small k-means over a downsampled copy of src and
+// returns the cluster closest to the average color of the edge strips.
+func (p *Processor) dominantColor(src image.Image, sb, db image.Rectangle) color.Color {
+	const (
+		k          = 4
+		iterations = 8
+		sampleSide = 48
+	)
+
+	samples := downsampleLinear(src, sampleSide, sampleSide)
+	centers, _ := kmeans(samples, k, iterations)
+
+	edge := p.edgeAverageColor(src, sb, db)
+	er, eg, eb, _ := edge.RGBA()
+	target := [3]float64{
+		srgbToLinear(float64(er) / 0xffff),
+		srgbToLinear(float64(eg) / 0xffff),
+		srgbToLinear(float64(eb) / 0xffff),
+	}
+
+	best, bestDist := centers[0], math.Inf(1)
+	for _, c := range centers {
+		if d := sqDist(c, target); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+
+	return color.NRGBA{
+		R: linearToSRGB8(best[0]),
+		G: linearToSRGB8(best[1]),
+		B: linearToSRGB8(best[2]),
+		A: 0xff,
+	}
+}
+
+// sqDist returns the squared euclidean distance between two linear RGB
+// triples.
+func sqDist(a, b [3]float64) float64 {
+	dr := a[0] - b[0]
+	dg := a[1] - b[1]
+	db := a[2] - b[2]
+	return dr*dr + dg*dg + db*db
+}
+
+// downsampleLinear returns up to w*h linear RGB samples of src, one per
+// grid cell.
+func downsampleLinear(src image.Image, w, h int) [][3]float64 {
+	b := src.Bounds()
+	samples := make([][3]float64, 0, w*h)
+
+	for yi := 0; yi < h; yi++ {
+		y := b.Min.Y + yi*b.Dy()/h
+		for xi := 0; xi < w; xi++ {
+			x := b.Min.X + xi*b.Dx()/w
+			r, g, bl, _ := src.At(x, y).RGBA()
+			samples = append(samples, [3]float64{
+				srgbToLinear(float64(r) / 0xffff),
+				srgbToLinear(float64(g) / 0xffff),
+				srgbToLinear(float64(bl) / 0xffff),
+			})
+		}
+	}
+
+	return samples
+}
+
+// blurredBackground stretches src to fill db and applies a heavy box
+// blur, approximating a large gaussian blur cheaply.
+func blurredBackground(src image.Image, db image.Rectangle) image.Image {
+	stretched := stretchNearest(src, db.Dx(), db.Dy())
+	return boxBlur(stretched, db.Dx()/20+1, 3)
+}
+
+// stretchNearest resizes src to w x h using nearest-neighbor sampling.
+func stretchNearest(src image.Image, w, h int) *image.NRGBA {
+	sb := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		sy := sb.Min.Y + y*sb.Dy()/h
+		for x := 0; x < w; x++ {
+			sx := sb.Min.X + x*sb.Dx()/w
+			dst.Set(x, y, src.At(sx, sy))
+		}
+	}
+	return dst
+}
+
+// boxBlur applies radius-r box blur, repeated passes times, separably
+// across x and y. Repeated box blurs approximate a gaussian blur.
+func boxBlur(src *image.NRGBA, radius, passes int) *image.NRGBA {
+	img := src
+	for i := 0; i < passes; i++ {
+		img = boxBlurH(img, radius)
+		img = boxBlurV(img, radius)
+	}
+	return img
+}
+
+func boxBlurH(src *image.NRGBA, radius int) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			var r, g, bl, n float64
+			for dx := -radius; dx <= radius; dx++ {
+				sx := x + dx
+				if sx < b.Min.X || sx >= b.Max.X {
+					continue
+				}
+				cr, cg, cb, _ := src.At(sx, y).RGBA()
+				r += float64(cr)
+				g += float64(cg)
+				bl += float64(cb)
+				n++
+			}
+			dst.Set(x, y, color.NRGBA{
+				R: uint8(r / n / 0x101),
+				G: uint8(g / n / 0x101),
+				B: uint8(bl / n / 0x101),
+				A: 0xff,
+			})
+		}
+	}
+	return dst
+}
+
+func boxBlurV(src *image.NRGBA, radius int) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(b)
+	for x := b.Min.X; x < b.Max.X; x++ {
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			var r, g, bl, n float64
+			for dy := -radius; dy <= radius; dy++ {
+				sy := y + dy
+				if sy < b.Min.Y || sy >= b.Max.Y {
+					continue
+				}
+				cr, cg, cb, _ := src.At(x, sy).RGBA()
+				r += float64(cr)
+				g += float64(cg)
+				bl += float64(cb)
+				n++
+			}
+			dst.Set(x, y, color.NRGBA{
+				R: uint8(r / n / 0x101),
+				G: uint8(g / n / 0x101),
+				B: uint8(bl / n / 0x101),
+				A: 0xff,
+			})
+		}
+	}
+	return dst
+}
+
+// srgbToLinear converts an sRGB channel value in [0,1] to linear light.
+func srgbToLinear(c float64) float64 {
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+// linearToSRGB8 converts a linear light channel value in [0,1] to an
+// 8-bit sRGB channel value.
+func linearToSRGB8(c float64) uint8 {
+	if c <= 0.0031308 {
+		c = c * 12.92
+	} else {
+		c = 1.055*math.Pow(c, 1/2.4) - 0.055
+	}
+	if c < 0 {
+		c = 0
+	}
+	if c > 1 {
+		c = 1
+	}
+	return uint8(c*255 + 0.5)
+}
@@ -0,0 +1,24 @@
+package letterbox
+
+import (
+	"image"
+	"testing"
+)
+
+func TestWithEdgeStripWidth(t *testing.T) {
+	sb := image.Rect(0, 0, 100, 50)
+	db := image.Rect(0, 0, 100, 100) // bars above/below
+
+	p, err := New("out", WithEdgeStripWidth(20))
+	if err != nil {
+		t.Fatalf("New: %s", err)
+	}
+
+	strips := p.edgeStrips(sb, db)
+	if len(strips) == 0 {
+		t.Fatal("expected at least one strip")
+	}
+	if got := strips[0].Dy(); got != 20 {
+		t.Errorf("strip height = %d, want %d", got, 20)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build avif
+
+package main
+
+// avifExtensions recognizes .avif input when built with `-tags avif`,
+// pulling in letterbox's cgo-backed AVIF decoder.
+var avifExtensions = []string{".avif"}
@@ -0,0 +1,6 @@
+//go:build !avif
+
+package main
+
+// avifExtensions is empty unless built with `-tags avif`.
+var avifExtensions []string
@@ -3,55 +3,126 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/tj/letterbox"
 )
 
 func main() {
-	dir := flag.String("output", "processed", "Image output directory")
+	dir := flag.String("output", "processed", "Image output directory, or s3://bucket/prefix")
 	white := flag.Bool("white", false, "Output a white letterbox")
 	aspect := flag.String("aspect", "16:9", "Output aspect ratio")
 	quality := flag.Int("quality", 90, "Output jpeg quality")
 	padding := flag.Int("padding", 0, "Output image padding in percentage")
 	concurrency := flag.Int("concurrency", runtime.NumCPU(), "Concurrency of image processing")
 	force := flag.Bool("force", false, "Force image reprocess when it exists")
+	autoOrient := flag.Bool("auto-orient", true, "Honor EXIF orientation before letterboxing")
+	background := flag.String("background", "", "Background mode: black, white, edge-average, dominant, or blur")
+	outputFormat := flag.String("format", "", "Output format: jpeg, png, or webp (default: inferred from extension)")
+	transparent := flag.Bool("transparent", false, "Preserve source alpha instead of filling the background (png, webp)")
+	sizes := flag.String("sizes", "", "Comma-separated WxH sizes to additionally resample to, e.g. 1920x1080,1280x720")
+	maxDimension := flag.Int("max-dimension", 0, "Downscale sources whose largest side exceeds this many pixels")
+	sidecar := flag.Bool("sidecar", false, "Write a .json sidecar with a blurhash, dominant color, and dimensions")
+	edgeStripWidth := flag.Int("edge-strip-width", 8, "Width in pixels of the edge strip sampled for the edge-average and dominant background modes")
 	flag.Parse()
 
-	// create destination directory
-	err := os.MkdirAll(*dir, 0755)
+	options := []letterbox.Option{
+		letterbox.WithWhiteBackground(*white),
+		letterbox.WithConcurrency(*concurrency),
+		letterbox.WithQuality(*quality),
+		letterbox.WithForce(*force),
+		letterbox.WithAspect(*aspect),
+		letterbox.WithPadding(*padding),
+		letterbox.WithAutoOrient(*autoOrient),
+		letterbox.WithTransparentBackground(*transparent),
+		letterbox.WithSidecar(*sidecar),
+		letterbox.WithEdgeStripWidth(*edgeStripWidth),
+	}
+
+	// destination, local or s3://bucket/prefix
+	destFS, destPath, err := parseLocation(*dir)
 	if err != nil {
-		log.Fatalf("error creating output directory: %s\n", err)
+		log.Fatalf("error parsing output location: %s", err)
+	}
+	if destFS == nil {
+		if err := os.MkdirAll(destPath, 0755); err != nil {
+			log.Fatalf("error creating output directory: %s\n", err)
+		}
+	} else {
+		options = append(options, letterbox.WithDest(destFS))
 	}
 
-	// images explicitly passed, or inferred
+	// images explicitly passed, or inferred from the local directory
 	images := flag.Args()
 	if len(images) == 0 {
 		images, err = listImages(".")
 		if err != nil {
 			log.Fatalf("error listing images: %s", err)
 		}
+	} else if sourceFS, _, err := parseLocation(images[0]); err == nil && sourceFS != nil {
+		options = append(options, letterbox.WithSource(sourceFS))
+
+		var expanded []string
+		for _, img := range images {
+			_, key, err := parseLocation(img)
+			if err != nil {
+				log.Fatalf("error parsing source location %q: %s", img, err)
+			}
+
+			if !strings.ContainsAny(key, "*?[") {
+				expanded = append(expanded, key)
+				continue
+			}
+
+			lister, ok := sourceFS.(letterbox.ListFS)
+			if !ok {
+				log.Fatalf("source %q does not support wildcard expansion", img)
+			}
+
+			matches, err := lister.List(key)
+			if err != nil {
+				log.Fatalf("error listing %q: %s", img, err)
+			}
+			expanded = append(expanded, matches...)
+		}
+		images = expanded
 	}
 
 	// process
 	start := time.Now()
 	log.Printf("Processing %d images\n", len(images))
 
-	processor, err := letterbox.New(*dir,
-		letterbox.WithWhiteBackground(*white),
-		letterbox.WithConcurrency(*concurrency),
-		letterbox.WithQuality(*quality),
-		letterbox.WithForce(*force),
-		letterbox.WithAspect(*aspect),
-		letterbox.WithPadding(*padding),
-	)
+	if *background != "" {
+		options = append(options, letterbox.WithBackgroundMode(*background))
+	}
+
+	if *outputFormat != "" {
+		options = append(options, letterbox.WithOutputFormat(*outputFormat))
+	}
+
+	if *maxDimension > 0 {
+		options = append(options, letterbox.WithMaxDimension(*maxDimension))
+	}
+
+	parsedSizes, err := parseSizes(*sizes)
+	if err != nil {
+		log.Fatalf("error parsing sizes: %s", err)
+	}
+	if len(parsedSizes) > 0 {
+		options = append(options, letterbox.WithSizes(parsedSizes))
+	}
 
+	processor, err := letterbox.New(destPath, options...)
 	if err != nil {
 		log.Fatalf("error creating proessor: %s", err)
 	}
@@ -65,6 +136,65 @@ func main() {
 	log.Printf("Processed in %s\n", time.Since(start).Round(time.Second))
 }
 
+// parseLocation parses a CLI location into an FS and the path/key to
+// use within it. A nil FS means raw should be treated as a local path.
+func parseLocation(raw string) (letterbox.FS, string, error) {
+	switch {
+	case strings.HasPrefix(raw, "s3://"):
+		bucket, prefix, _ := strings.Cut(strings.TrimPrefix(raw, "s3://"), "/")
+
+		cfg, err := config.LoadDefaultConfig(context.Background())
+		if err != nil {
+			return nil, "", fmt.Errorf("loading aws config: %w", err)
+		}
+
+		return letterbox.S3FS{
+			Client: s3.NewFromConfig(cfg),
+			Bucket: bucket,
+		}, prefix, nil
+
+	case strings.HasPrefix(raw, "http://"), strings.HasPrefix(raw, "https://"):
+		scheme, rest, _ := strings.Cut(raw, "://")
+		host, path, _ := strings.Cut(rest, "/")
+		return letterbox.HTTPFS{BaseURL: scheme + "://" + host + "/"}, path, nil
+
+	default:
+		return nil, raw, nil
+	}
+}
+
+// parseSizes parses a comma-separated "WxH,WxH" list into sizes.
+func parseSizes(s string) (sizes []letterbox.Size, err error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	for _, part := range strings.Split(s, ",") {
+		dims := strings.Split(part, "x")
+		if len(dims) != 2 {
+			return nil, fmt.Errorf("invalid size %q, expected WxH", part)
+		}
+
+		w, err := strconv.Atoi(dims[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid size %q: %w", part, err)
+		}
+
+		h, err := strconv.Atoi(dims[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid size %q: %w", part, err)
+		}
+
+		sizes = append(sizes, letterbox.Size{Width: w, Height: h})
+	}
+
+	return sizes, nil
+}
+
+// imageExtensions are the file extensions listImages treats as input
+// images. avifExtensions, built with `-tags avif`, appends ".avif".
+var imageExtensions = append([]string{".jpg", ".jpeg", ".tif", ".png", ".webp"}, avifExtensions...)
+
 // listImages returns the images in the given directory.
 func listImages(dir string) (images []string, err error) {
 	files, err := ioutil.ReadDir(dir)
@@ -74,8 +204,11 @@ func listImages(dir string) (images []string, err error) {
 
 	for _, f := range files {
 		ext := strings.ToLower(filepath.Ext(f.Name()))
-		if ext == ".jpg" || ext == ".jpeg" || ext == ".tif" {
-			images = append(images, filepath.Join(dir, f.Name()))
+		for _, known := range imageExtensions {
+			if ext == known {
+				images = append(images, filepath.Join(dir, f.Name()))
+				break
+			}
 		}
 	}
 
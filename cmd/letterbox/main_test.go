@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/tj/letterbox"
+)
+
+func TestParseSizes(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    []letterbox.Size
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"1920x1080", []letterbox.Size{{Width: 1920, Height: 1080}}, false},
+		{"1920x1080,1280x720", []letterbox.Size{{Width: 1920, Height: 1080}, {Width: 1280, Height: 720}}, false},
+		{"1920", nil, true},
+		{"axb", nil, true},
+	}
+
+	for _, c := range cases {
+		got, err := parseSizes(c.in)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("parseSizes(%q): expected error, got none", c.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseSizes(%q): unexpected error: %s", c.in, err)
+			continue
+		}
+		if len(got) != len(c.want) {
+			t.Errorf("parseSizes(%q) = %v, want %v", c.in, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("parseSizes(%q) = %v, want %v", c.in, got, c.want)
+				break
+			}
+		}
+	}
+}
+
+func TestParseLocationLocal(t *testing.T) {
+	fs, key, err := parseLocation("output/dir")
+	if err != nil {
+		t.Fatalf("parseLocation: %s", err)
+	}
+	if fs != nil {
+		t.Errorf("parseLocation(local path): expected nil FS, got %T", fs)
+	}
+	if key != "output/dir" {
+		t.Errorf("parseLocation(local path): key = %q, want %q", key, "output/dir")
+	}
+}
+
+func TestParseLocationHTTP(t *testing.T) {
+	fs, key, err := parseLocation("https://example.com/images/src")
+	if err != nil {
+		t.Fatalf("parseLocation: %s", err)
+	}
+
+	http, ok := fs.(letterbox.HTTPFS)
+	if !ok {
+		t.Fatalf("parseLocation(https url): expected HTTPFS, got %T", fs)
+	}
+	if http.BaseURL != "https://example.com/" {
+		t.Errorf("parseLocation(https url): BaseURL = %q, want %q", http.BaseURL, "https://example.com/")
+	}
+	if key != "images/src" {
+		t.Errorf("parseLocation(https url): key = %q, want %q", key, "images/src")
+	}
+}
@@ -0,0 +1,132 @@
+package letterbox
+
+import (
+	"image"
+	"io"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// orientation is an EXIF Orientation tag value (1-8).
+//
+// See https://www.exif.org/Exif2-2.PDF section 4.6.4.A for the full table.
+type orientation int
+
+// Orientation values, matching the EXIF spec.
+const (
+	orientationNormal     orientation = 1
+	orientationFlipH      orientation = 2
+	orientationRotate180  orientation = 3
+	orientationFlipV      orientation = 4
+	orientationTranspose  orientation = 5
+	orientationRotate90   orientation = 6
+	orientationTransverse orientation = 7
+	orientationRotate270  orientation = 8
+)
+
+// readOrientation returns the EXIF Orientation tag from r, or
+// orientationNormal if it's absent or unreadable.
+func readOrientation(r io.Reader) orientation {
+	x, err := exif.Decode(r)
+	if err != nil {
+		return orientationNormal
+	}
+
+	tag, err := x.Get(exif.Orientation)
+	if err != nil {
+		return orientationNormal
+	}
+
+	n, err := tag.Int(0)
+	if err != nil {
+		return orientationNormal
+	}
+
+	return orientation(n)
+}
+
+// applyOrientation returns src transformed so that it displays
+// upright, undoing whatever o describes.
+func applyOrientation(src image.Image, o orientation) image.Image {
+	switch o {
+	case orientationFlipH:
+		return flipH(src)
+	case orientationRotate180:
+		return rotate180(src)
+	case orientationFlipV:
+		return flipV(src)
+	case orientationTranspose:
+		return transpose(src)
+	case orientationRotate90:
+		return rotate90(src)
+	case orientationTransverse:
+		return transverse(src)
+	case orientationRotate270:
+		return rotate270(src)
+	default:
+		return src
+	}
+}
+
+// flipH mirrors src left-to-right.
+func flipH(src image.Image) image.Image {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			dst.Set(b.Dx()-1-x, y, src.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// flipV mirrors src top-to-bottom.
+func flipV(src image.Image) image.Image {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			dst.Set(x, b.Dy()-1-y, src.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// rotate180 rotates src by 180 degrees.
+func rotate180(src image.Image) image.Image {
+	return flipV(flipH(src))
+}
+
+// rotate90 rotates src clockwise by 90 degrees.
+func rotate90(src image.Image) image.Image {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dy(), b.Dx()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			dst.Set(b.Dy()-1-y, x, src.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// rotate270 rotates src clockwise by 270 degrees (counter-clockwise by 90).
+func rotate270(src image.Image) image.Image {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dy(), b.Dx()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			dst.Set(y, b.Dx()-1-x, src.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// transpose flips src across its top-left to bottom-right diagonal.
+func transpose(src image.Image) image.Image {
+	return rotate270(flipH(src))
+}
+
+// transverse flips src across its top-right to bottom-left diagonal.
+func transverse(src image.Image) image.Image {
+	return rotate90(flipH(src))
+}
@@ -0,0 +1,92 @@
+package letterbox
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// grayGrid returns a w x h image where every pixel has a unique gray
+// value, so any transform's output can be checked pixel by pixel.
+func grayGrid(w, h int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetGray(x, y, color.Gray{Y: uint8(y*w + x)})
+		}
+	}
+	return img
+}
+
+// grayAt reads a pixel as a gray value, regardless of the underlying
+// image type.
+func grayAt(img image.Image, x, y int) uint8 {
+	r, _, _, _ := img.At(x, y).RGBA()
+	return uint8(r >> 8)
+}
+
+// rawForOrientation builds the image a camera would have stored under
+// EXIF Orientation tag o, for the given upright (correctly displayed)
+// image. The formulas are independent, textbook definitions of
+// mirroring/rotation, not derived from this package's transform code.
+func rawForOrientation(o orientation, upright *image.Gray) image.Image {
+	b := upright.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	build := func(rw, rh int, px func(x, y int) uint8) *image.Gray {
+		raw := image.NewGray(image.Rect(0, 0, rw, rh))
+		for y := 0; y < rh; y++ {
+			for x := 0; x < rw; x++ {
+				raw.SetGray(x, y, color.Gray{Y: px(x, y)})
+			}
+		}
+		return raw
+	}
+
+	at := func(x, y int) uint8 { return grayAt(upright, x, y) }
+
+	switch o {
+	case orientationNormal:
+		return upright
+	case orientationFlipH: // mirror left-right, self-inverse
+		return build(w, h, func(x, y int) uint8 { return at(w-1-x, y) })
+	case orientationRotate180: // self-inverse
+		return build(w, h, func(x, y int) uint8 { return at(w-1-x, h-1-y) })
+	case orientationFlipV: // mirror top-bottom, self-inverse
+		return build(w, h, func(x, y int) uint8 { return at(x, h-1-y) })
+	case orientationTranspose: // main-diagonal reflection, self-inverse
+		return build(h, w, func(x, y int) uint8 { return at(y, x) })
+	case orientationRotate90: // corrected by rotating 90 CW, so raw is rotated 270 CW
+		return build(h, w, func(x, y int) uint8 { return at(w-1-y, x) })
+	case orientationTransverse: // anti-diagonal reflection, self-inverse
+		return build(h, w, func(x, y int) uint8 { return at(w-1-y, h-1-x) })
+	case orientationRotate270: // corrected by rotating 270 CW, so raw is rotated 90 CW
+		return build(h, w, func(x, y int) uint8 { return at(y, h-1-x) })
+	default:
+		return upright
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	const w, h = 4, 3
+	upright := grayGrid(w, h)
+
+	for o := orientationNormal; o <= orientationRotate270; o++ {
+		raw := rawForOrientation(o, upright)
+
+		got := applyOrientation(raw, o)
+		gb := got.Bounds()
+		if gb.Dx() != w || gb.Dy() != h {
+			t.Errorf("orientation %d: got size %dx%d, want %dx%d", o, gb.Dx(), gb.Dy(), w, h)
+			continue
+		}
+
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				if g, u := grayAt(got, x, y), grayAt(upright, x, y); g != u {
+					t.Errorf("orientation %d: pixel (%d,%d) = %d, want %d", o, x, y, g, u)
+				}
+			}
+		}
+	}
+}
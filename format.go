@@ -0,0 +1,98 @@
+package letterbox
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"strings"
+)
+
+// outputFormatFor returns the output format for dstpath: the explicit
+// WithOutputFormat value if set, otherwise inferred from the
+// destination extension, falling back to jpeg.
+func (p *Processor) outputFormatFor(dstpath string) string {
+	if p.outputFormat != "" {
+		return p.outputFormat
+	}
+
+	switch strings.ToLower(filepath.Ext(dstpath)) {
+	case ".png":
+		return "png"
+	case ".webp":
+		return "webp"
+	default:
+		return "jpeg"
+	}
+}
+
+// extFor returns the file extension, including the leading dot, for
+// the given output format.
+func extFor(format string) string {
+	switch format {
+	case "png":
+		return ".png"
+	case "webp":
+		return ".webp"
+	default:
+		return ".jpg"
+	}
+}
+
+// withOutputExt swaps the extension of path for the one matching
+// format, used when WithOutputFormat requests a different format than
+// the source extension implies.
+func withOutputExt(path, format string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + extFor(format)
+}
+
+// hasAlphaChannel reports whether img actually contains any
+// partially or fully transparent pixels, used to decide whether to
+// preserve transparency instead of filling the letterbox background.
+// For a paletted image it reports whether any palette entry is
+// transparent, whether or not a pixel uses that entry.
+//
+// The color model alone isn't enough: Go's PNG decoder returns
+// *image.RGBA for any ordinary opaque truecolor PNG, and every
+// EXIF rotation/flip in exif.go allocates an *image.RGBA regardless
+// of whether the source ever had transparent pixels.
+func hasAlphaChannel(img image.Image) bool {
+	if p, ok := img.(*image.Paletted); ok {
+		return paletteHasAlpha(p.Palette)
+	}
+
+	switch img.ColorModel() {
+	case color.NRGBAModel, color.NRGBA64Model, color.RGBAModel, color.RGBA64Model,
+		color.NYCbCrAModel, color.AlphaModel, color.Alpha16Model:
+		return scanHasAlpha(img)
+	default:
+		return false
+	}
+}
+
+// scanHasAlpha reports whether any pixel of img is partially or fully
+// transparent.
+func scanHasAlpha(img image.Image) bool {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// paletteHasAlpha reports whether any entry of pal is partially or
+// fully transparent, which is how palette-mode PNGs (e.g. a tRNS
+// chunk) carry transparency.
+func paletteHasAlpha(pal color.Palette) bool {
+	for _, c := range pal {
+		_, _, _, a := c.RGBA()
+		if a != 0xffff {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,9 @@
+//go:build avif
+
+package letterbox
+
+// AVIF decoding is optional: it pulls in a cgo dependency, so it's only
+// registered when built with `-tags avif`.
+import (
+	_ "github.com/gen2brain/avif"
+)
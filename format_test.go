@@ -0,0 +1,63 @@
+package letterbox
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestHasAlphaChannelPaletted(t *testing.T) {
+	opaque := color.Palette{
+		color.NRGBA{R: 0xff, G: 0, B: 0, A: 0xff},
+		color.NRGBA{R: 0, G: 0xff, B: 0, A: 0xff},
+	}
+	transparent := color.Palette{
+		color.NRGBA{R: 0xff, G: 0, B: 0, A: 0xff},
+		color.NRGBA{R: 0, G: 0xff, B: 0, A: 0},
+	}
+
+	cases := []struct {
+		name string
+		pal  color.Palette
+		want bool
+	}{
+		{"opaque palette", opaque, false},
+		{"palette with tRNS entry", transparent, true},
+	}
+
+	for _, c := range cases {
+		img := image.NewPaletted(image.Rect(0, 0, 2, 2), c.pal)
+		if got := hasAlphaChannel(img); got != c.want {
+			t.Errorf("%s: hasAlphaChannel = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestHasAlphaChannelRGBA(t *testing.T) {
+	opaque := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	draw.Draw(opaque, opaque.Bounds(), &image.Uniform{color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}}, image.ZP, draw.Src)
+
+	transparent := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	draw.Draw(transparent, transparent.Bounds(), &image.Uniform{color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}}, image.ZP, draw.Src)
+	transparent.SetRGBA(1, 1, color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0x80})
+
+	opaqueN := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	draw.Draw(opaqueN, opaqueN.Bounds(), &image.Uniform{color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}}, image.ZP, draw.Src)
+
+	cases := []struct {
+		name string
+		img  image.Image
+		want bool
+	}{
+		{"opaque RGBA (e.g. a rotated/flipped opaque JPEG)", opaque, false},
+		{"RGBA with a transparent pixel", transparent, true},
+		{"opaque NRGBA (e.g. a decoded truecolor+alpha PNG with no transparent pixels)", opaqueN, false},
+	}
+
+	for _, c := range cases {
+		if got := hasAlphaChannel(c.img); got != c.want {
+			t.Errorf("%s: hasAlphaChannel = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
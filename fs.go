@@ -0,0 +1,103 @@
+package letterbox
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// joinPath joins dir and name with a forward slash, suitable for both
+// local paths and object-storage keys/URLs.
+func joinPath(dir, name string) string {
+	if dir == "" {
+		return name
+	}
+	return path.Join(dir, name)
+}
+
+// FS abstracts reading and writing the images a Processor operates on,
+// so sources and destinations aren't limited to the local filesystem.
+// Shipped implementations are localFS, S3FS, and HTTPFS (read-only);
+// there is no Google Cloud Storage implementation, though one could be
+// added by implementing FS against the GCS client library.
+type FS interface {
+	// Open returns a reader for path along with its modification time.
+	Open(path string) (io.ReadCloser, time.Time, error)
+
+	// Create returns a writer for path, creating or truncating it.
+	Create(path string) (io.WriteCloser, error)
+
+	// Stat returns the modification time of path and whether it exists.
+	// A non-existent path is not an error.
+	Stat(path string) (time.Time, bool, error)
+}
+
+// ListFS is implemented by FS backends that can expand a glob pattern
+// into the paths matching it, such as S3. The CLI uses it to support
+// wildcard source arguments like s3://bucket/prefix/*.jpg, since
+// there's no shell to expand them for us. Backends without a native
+// listing call, like localFS and HTTPFS, don't implement it.
+type ListFS interface {
+	// List returns every path matching pattern, a glob applied to the
+	// final path segment using the rules of path.Match.
+	List(pattern string) ([]string, error)
+}
+
+// WithSource changes where images are read from. Defaults to the local
+// filesystem.
+func WithSource(fs FS) Option {
+	return func(p *Processor) error {
+		p.source = fs
+		return nil
+	}
+}
+
+// WithDest changes where images are written to. Defaults to the local
+// filesystem.
+func WithDest(fs FS) Option {
+	return func(p *Processor) error {
+		p.dest = fs
+		return nil
+	}
+}
+
+// localFS is the default FS, backed by the local filesystem.
+type localFS struct{}
+
+// Open implements FS.
+func (localFS) Open(path string) (io.ReadCloser, time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, time.Time{}, err
+	}
+
+	return f, fi.ModTime(), nil
+}
+
+// Create implements FS.
+func (localFS) Create(path string) (io.WriteCloser, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return os.Create(path)
+}
+
+// Stat implements FS.
+func (localFS) Stat(path string) (time.Time, bool, error) {
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return fi.ModTime(), true, nil
+}
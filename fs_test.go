@@ -0,0 +1,20 @@
+package letterbox
+
+import "testing"
+
+func TestJoinPath(t *testing.T) {
+	cases := []struct {
+		dir, name, want string
+	}{
+		{"", "photo.jpg", "photo.jpg"},
+		{"prefix", "photo.jpg", "prefix/photo.jpg"},
+		{"prefix/", "photo.jpg", "prefix/photo.jpg"},
+		{"prefix", "sub/photo.jpg", "prefix/sub/photo.jpg"},
+	}
+
+	for _, c := range cases {
+		if got := joinPath(c.dir, c.name); got != c.want {
+			t.Errorf("joinPath(%q, %q) = %q, want %q", c.dir, c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package letterbox
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPFS is a read-only FS that resolves paths against BaseURL, for
+// pulling source images over HTTP instead of staging them locally.
+type HTTPFS struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// Open implements FS. The response Last-Modified header is used as the
+// modification time, or the zero time if absent.
+func (h HTTPFS) Open(path string) (io.ReadCloser, time.Time, error) {
+	resp, err := h.client().Get(h.BaseURL + path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, time.Time{}, fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return resp.Body, modTime, nil
+}
+
+// Create implements FS, and always fails: HTTPFS is read-only.
+func (h HTTPFS) Create(path string) (io.WriteCloser, error) {
+	return nil, fmt.Errorf("letterbox: HTTPFS is read-only, cannot create %s", path)
+}
+
+// Stat implements FS using a HEAD request.
+func (h HTTPFS) Stat(path string) (time.Time, bool, error) {
+	resp, err := h.client().Head(h.BaseURL + path)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return time.Time{}, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, false, fmt.Errorf("HEAD %s: %s", path, resp.Status)
+	}
+
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return modTime, true, nil
+}
+
+// client returns h.Client, or http.DefaultClient if unset.
+func (h HTTPFS) client() *http.Client {
+	if h.Client != nil {
+		return h.Client
+	}
+	return http.DefaultClient
+}
@@ -1,19 +1,23 @@
 package letterbox
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
 	"image/jpeg"
+	"image/png"
+	"io"
 	"log"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/disintegration/imaging"
 	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
 
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/sync/semaphore"
@@ -25,13 +29,26 @@ type Option func(*Processor) error
 // Processor is a batch image processor for automating
 // cropping and letterboxes.
 type Processor struct {
-	dir         string
-	white       bool
-	aspect      float64
-	quality     int
-	concurrency int
-	padding     float64
-	force       bool
+	dir            string
+	white          bool
+	aspect         float64
+	quality        int
+	concurrency    int
+	padding        float64
+	force          bool
+	autoOrient     bool
+	backgroundMode string
+	outputFormat   string
+	transparentBg  bool
+	sizes          []Size
+	resampleFilter imaging.ResampleFilter
+	maxDimension   int
+	blurhashX      int
+	blurhashY      int
+	sidecar        bool
+	edgeStripWidth int
+	source         FS
+	dest           FS
 }
 
 // New processor outputting to dir with the given options.
@@ -39,6 +56,13 @@ func New(dir string, options ...Option) (*Processor, error) {
 	var v Processor
 	v.concurrency = 1
 	v.quality = 90
+	v.autoOrient = true
+	v.resampleFilter = imaging.Lanczos
+	v.blurhashX = 4
+	v.blurhashY = 3
+	v.edgeStripWidth = defaultEdgeStripWidth
+	v.source = localFS{}
+	v.dest = localFS{}
 	v.dir = dir
 	for _, o := range options {
 		if err := o(&v); err != nil {
@@ -97,6 +121,59 @@ func WithConcurrency(n int) Option {
 	}
 }
 
+// WithBackgroundMode changes how the letterbox background is derived.
+// Valid modes are "black", "white", "edge-average" (averages a strip of
+// the source along the edges the bars cover), "dominant" (k-means over
+// the source, picking the cluster closest to the edges), and "blur"
+// (a stretched, heavily blurred copy of the source). Defaults to black
+// or white depending on WithWhiteBackground.
+func WithBackgroundMode(mode string) Option {
+	return func(p *Processor) error {
+		switch mode {
+		case "black", "white", "edge-average", "dominant", "blur":
+			p.backgroundMode = mode
+			return nil
+		default:
+			return fmt.Errorf("letterbox: unknown background mode %q", mode)
+		}
+	}
+}
+
+// WithOutputFormat overrides the output format, one of "jpeg", "png",
+// or "webp". When unset the format is inferred from the destination
+// file extension, falling back to jpeg.
+func WithOutputFormat(format string) Option {
+	return func(p *Processor) error {
+		switch format {
+		case "jpeg", "png", "webp":
+			p.outputFormat = format
+			return nil
+		default:
+			return fmt.Errorf("letterbox: unknown output format %q", format)
+		}
+	}
+}
+
+// WithTransparentBackground preserves the source alpha channel instead
+// of filling the letterbox bars with a background, for lossless output
+// formats (png, webp).
+func WithTransparentBackground(v bool) Option {
+	return func(p *Processor) error {
+		p.transparentBg = v
+		return nil
+	}
+}
+
+// WithAutoOrient changes whether the EXIF Orientation tag is honored,
+// rotating and flipping the source image so it's decoded upright
+// before letterboxing. Defaults to true.
+func WithAutoOrient(v bool) Option {
+	return func(p *Processor) error {
+		p.autoOrient = v
+		return nil
+	}
+}
+
 // Process the given images.
 func (p *Processor) Process(ctx context.Context, images []string) error {
 	sem := semaphore.NewWeighted(int64(p.concurrency))
@@ -120,45 +197,91 @@ func (p *Processor) Process(ctx context.Context, images []string) error {
 
 // process implementation.
 func (p *Processor) process(path string) error {
-	dstpath := filepath.Join(p.dir, path)
+	dstpath := joinPath(p.dir, path)
+	format := p.outputFormatFor(dstpath)
+	if p.outputFormat != "" {
+		dstpath = withOutputExt(dstpath, p.outputFormat)
+	}
 
 	// unmodified
-	if unmodified(path, dstpath) && !p.force {
+	if !p.force && p.unmodified(path, dstpath) {
 		log.Printf("Unmodified %s", path)
 		return nil
 	}
 
-	// open
+	// open. FS only exposes a reader, so buffer the whole image to allow
+	// both reading the EXIF orientation and decoding it from the start.
 	log.Printf("Processing %s\n", path)
-	f, err := os.Open(path)
+	r, _, err := p.source.Open(path)
 	if err != nil {
 		return fmt.Errorf("opening: %w", err)
 	}
-	defer f.Close()
+	defer r.Close()
+
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("reading: %w", err)
+	}
+
+	o := orientationNormal
+	if p.autoOrient {
+		o = readOrientation(bytes.NewReader(buf))
+	}
 
 	// decode
-	src, _, err := image.Decode(f)
+	src, _, err := image.Decode(bytes.NewReader(buf))
 	if err != nil {
 		return fmt.Errorf("decoding: %w", err)
 	}
 
+	// rotate/flip into an upright orientation. the output is re-encoded
+	// from raw pixels below, so the original Orientation tag is dropped
+	// rather than carried forward.
+	src = applyOrientation(src, o)
+
+	// bound memory/CPU on huge sources
+	src = p.shrinkToMax(src)
+
 	// dimensions
 	sb := src.Bounds()
 	db := aspect(sb, p.aspect)
 	db = padding(db, p.padding)
 	dr := centered(sb, db)
 
-	// dst image
-	dst := image.NewRGBA(db)
+	// dst image. lossless formats with transparency requested keep the
+	// source alpha channel and skip the background fill entirely.
+	lossless := format != "jpeg"
+	transparent := lossless && p.transparentBg && hasAlphaChannel(src)
 
-	// fill the background with black or white
-	draw.Draw(dst, db, &image.Uniform{withColor(p.white)}, image.ZP, draw.Src)
+	var dst draw.Image
+	if transparent {
+		dst = image.NewNRGBA(db)
+	} else {
+		dst = image.NewRGBA(db)
+		draw.Draw(dst, db, p.background(src, sb, db), image.ZP, draw.Src)
+	}
 
 	// draw the src image onto dst
 	draw.Draw(dst, dr, src, src.Bounds().Min, draw.Src)
 
 	// write
-	return writeImage(dst, dstpath, p.quality)
+	if err := p.writeImage(dst, dstpath, format); err != nil {
+		return err
+	}
+
+	// additional resampled sizes
+	if err := p.writeSizes(dst, dstpath, format); err != nil {
+		return err
+	}
+
+	// blurhash/dominant-color sidecar
+	if p.sidecar {
+		if err := p.writeSidecar(dst, dstpath); err != nil {
+			return fmt.Errorf("writing sidecar: %w", err)
+		}
+	}
+
+	return nil
 }
 
 // withColor returns the color specified.
@@ -203,16 +326,28 @@ func aspect(r image.Rectangle, aspect float64) image.Rectangle {
 	return image.Rect(0, 0, int(w), int(h))
 }
 
-// writeImage writes a jpeg image to the given path.
-func writeImage(img image.Image, path string, quality int) error {
-	f, err := os.Create(path)
+// writeImage writes img to path through p.dest in the given format.
+func (p *Processor) writeImage(img image.Image, path, format string) (err error) {
+	w, err := p.dest.Create(path)
 	if err != nil {
 		return fmt.Errorf("creating: %w", err)
 	}
-
-	err = jpeg.Encode(f, img, &jpeg.Options{
-		Quality: quality,
-	})
+	defer func() {
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	switch format {
+	case "png":
+		err = png.Encode(w, img)
+	case "webp":
+		err = nativewebp.Encode(w, img, nil)
+	default:
+		err = jpeg.Encode(w, img, &jpeg.Options{
+			Quality: p.quality,
+		})
+	}
 
 	if err != nil {
 		return fmt.Errorf("encoding: %w", err)
@@ -241,23 +376,16 @@ func parseAspect(s string) (float64, error) {
 // unmodified returns true if the output image already exists,
 // and is newer than the source image. Errors are treated
 // as falsey.
-func unmodified(src, dst string) bool {
-	di, err := os.Stat(dst)
-
-	// doesn't exist
-	if os.IsNotExist(err) {
+func (p *Processor) unmodified(src, dst string) bool {
+	dt, exists, err := p.dest.Stat(dst)
+	if err != nil || !exists {
 		return false
 	}
 
-	// exists, compare modified times
-	si, err := os.Stat(src)
+	st, _, err := p.source.Stat(src)
 	if err != nil {
 		return false
 	}
 
-	if di.ModTime().After(si.ModTime()) {
-		return true
-	}
-
-	return false
+	return dt.After(st)
 }
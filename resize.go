@@ -0,0 +1,89 @@
+package letterbox
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+// Size is a width/height pair requested via WithSize or WithSizes.
+type Size struct {
+	Width  int
+	Height int
+}
+
+// WithSize adds a size to resample the letterboxed image to, written
+// alongside the full-size output as "name@WxH.ext". May be called
+// multiple times to request several sizes.
+func WithSize(w, h int) Option {
+	return func(p *Processor) error {
+		p.sizes = append(p.sizes, Size{Width: w, Height: h})
+		return nil
+	}
+}
+
+// WithSizes adds multiple sizes, see WithSize.
+func WithSizes(sizes []Size) Option {
+	return func(p *Processor) error {
+		p.sizes = append(p.sizes, sizes...)
+		return nil
+	}
+}
+
+// WithResampleFilter changes the resampling filter used when producing
+// sizes or downscaling with WithMaxDimension. Defaults to
+// imaging.Lanczos.
+func WithResampleFilter(f imaging.ResampleFilter) Option {
+	return func(p *Processor) error {
+		p.resampleFilter = f
+		return nil
+	}
+}
+
+// WithMaxDimension downscales source images whose largest dimension
+// exceeds px before letterboxing, bounding memory and CPU use on huge
+// sources. Disabled by default.
+func WithMaxDimension(px int) Option {
+	return func(p *Processor) error {
+		p.maxDimension = px
+		return nil
+	}
+}
+
+// shrinkToMax downscales src to fit within p.maxDimension on its
+// largest side, if set and exceeded.
+func (p *Processor) shrinkToMax(src image.Image) image.Image {
+	if p.maxDimension <= 0 {
+		return src
+	}
+
+	b := src.Bounds()
+	if b.Dx() <= p.maxDimension && b.Dy() <= p.maxDimension {
+		return src
+	}
+
+	return imaging.Fit(src, p.maxDimension, p.maxDimension, p.resampleFilter)
+}
+
+// writeSizes resamples dst to each requested size and writes it
+// alongside dstpath as "name@WxH.ext".
+func (p *Processor) writeSizes(dst image.Image, dstpath, format string) error {
+	for _, s := range p.sizes {
+		resized := imaging.Resize(dst, s.Width, s.Height, p.resampleFilter)
+		path := sizedPath(dstpath, s)
+		if err := p.writeImage(resized, path, format); err != nil {
+			return fmt.Errorf("resizing to %dx%d: %w", s.Width, s.Height, err)
+		}
+	}
+	return nil
+}
+
+// sizedPath returns dstpath with "@WxH" inserted before the extension.
+func sizedPath(dstpath string, s Size) string {
+	ext := filepath.Ext(dstpath)
+	base := strings.TrimSuffix(dstpath, ext)
+	return fmt.Sprintf("%s@%dx%d%s", base, s.Width, s.Height, ext)
+}
@@ -0,0 +1,51 @@
+package letterbox
+
+import (
+	"image"
+	"testing"
+)
+
+func TestSizedPath(t *testing.T) {
+	cases := []struct {
+		dstpath string
+		size    Size
+		want    string
+	}{
+		{"out/photo.jpg", Size{Width: 1920, Height: 1080}, "out/photo@1920x1080.jpg"},
+		{"out/photo.png", Size{Width: 128, Height: 128}, "out/photo@128x128.png"},
+		{"photo", Size{Width: 64, Height: 64}, "photo@64x64"},
+	}
+
+	for _, c := range cases {
+		if got := sizedPath(c.dstpath, c.size); got != c.want {
+			t.Errorf("sizedPath(%q, %+v) = %q, want %q", c.dstpath, c.size, got, c.want)
+		}
+	}
+}
+
+func TestShrinkToMax(t *testing.T) {
+	p, err := New("out", WithMaxDimension(100))
+	if err != nil {
+		t.Fatalf("New: %s", err)
+	}
+
+	large := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	shrunk := p.shrinkToMax(large)
+	b := shrunk.Bounds()
+	if b.Dx() > 100 || b.Dy() > 100 {
+		t.Errorf("shrinkToMax: got %dx%d, want both dimensions <= 100", b.Dx(), b.Dy())
+	}
+
+	small := image.NewRGBA(image.Rect(0, 0, 50, 50))
+	if got := p.shrinkToMax(small); got != image.Image(small) {
+		t.Error("shrinkToMax: resized an image already within the bound")
+	}
+
+	pNoLimit, err := New("out")
+	if err != nil {
+		t.Fatalf("New: %s", err)
+	}
+	if got := pNoLimit.shrinkToMax(large); got != image.Image(large) {
+		t.Error("shrinkToMax: resized with WithMaxDimension unset")
+	}
+}
@@ -0,0 +1,130 @@
+package letterbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3FS is an FS backed by an S3 bucket (or S3-compatible store).
+type S3FS struct {
+	Client *s3.Client
+	Bucket string
+	Prefix string
+}
+
+// Open implements FS.
+func (s S3FS) Open(path string) (io.ReadCloser, time.Time, error) {
+	out, err := s.Client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s.key(path)),
+	})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	modTime := time.Time{}
+	if out.LastModified != nil {
+		modTime = *out.LastModified
+	}
+
+	return out.Body, modTime, nil
+}
+
+// Create implements FS, buffering the write and uploading on Close.
+func (s S3FS) Create(path string) (io.WriteCloser, error) {
+	return &s3Writer{s3: s, key: s.key(path)}, nil
+}
+
+// Stat implements FS using a HeadObject request.
+func (s S3FS) Stat(path string) (time.Time, bool, error) {
+	out, err := s.Client.HeadObject(context.Background(), &s3.HeadObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s.key(path)),
+	})
+
+	var notFound *types.NotFound
+	if errors.As(err, &notFound) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	modTime := time.Time{}
+	if out.LastModified != nil {
+		modTime = *out.LastModified
+	}
+
+	return modTime, true, nil
+}
+
+// List implements ListFS, expanding pattern's final path segment as a
+// glob against the objects under its directory.
+func (s S3FS) List(pattern string) ([]string, error) {
+	dir, base := path.Split(pattern)
+
+	var keys []string
+	paginator := s3.NewListObjectsV2Paginator(s.Client, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(s.Bucket),
+		Prefix:    aws.String(s.key(dir)),
+		Delimiter: aws.String("/"),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range page.Contents {
+			keys = append(keys, path.Base(aws.ToString(obj.Key)))
+		}
+	}
+
+	return matchGlob(dir, base, keys), nil
+}
+
+// matchGlob filters names to those matching the glob base, rejoining
+// each with dir to form a path relative to the FS.
+func matchGlob(dir, base string, names []string) []string {
+	var matches []string
+	for _, name := range names {
+		if ok, _ := path.Match(base, name); ok {
+			matches = append(matches, path.Join(dir, name))
+		}
+	}
+	return matches
+}
+
+// key joins s.Prefix and path into an S3 object key.
+func (s S3FS) key(path string) string {
+	return joinPath(s.Prefix, path)
+}
+
+// s3Writer buffers a write in memory and uploads it as a single S3
+// object on Close, since S3 has no streaming append.
+type s3Writer struct {
+	s3  S3FS
+	key string
+	buf bytes.Buffer
+}
+
+func (w *s3Writer) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *s3Writer) Close() error {
+	_, err := w.s3.Client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket: aws.String(w.s3.Bucket),
+		Key:    aws.String(w.key),
+		Body:   bytes.NewReader(w.buf.Bytes()),
+	})
+	return err
+}
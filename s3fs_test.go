@@ -0,0 +1,49 @@
+package letterbox
+
+import "testing"
+
+func TestS3FSKey(t *testing.T) {
+	cases := []struct {
+		prefix, path, want string
+	}{
+		{"", "photo.jpg", "photo.jpg"},
+		{"images", "photo.jpg", "images/photo.jpg"},
+		{"images/", "sub/photo.jpg", "images/sub/photo.jpg"},
+	}
+
+	for _, c := range cases {
+		s := S3FS{Bucket: "bucket", Prefix: c.prefix}
+		if got := s.key(c.path); got != c.want {
+			t.Errorf("S3FS{Prefix: %q}.key(%q) = %q, want %q", c.prefix, c.path, got, c.want)
+		}
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	names := []string{"a.jpg", "b.jpg", "c.png", "a.jpeg"}
+
+	cases := []struct {
+		dir, base string
+		want      []string
+	}{
+		{"src/", "*.jpg", []string{"src/a.jpg", "src/b.jpg"}},
+		{"src/", "*.png", []string{"src/c.png"}},
+		{"", "*.jpg", []string{"a.jpg", "b.jpg"}},
+		{"src/", "*.gif", nil},
+		{"src/", "a.*", []string{"src/a.jpg", "src/a.jpeg"}},
+	}
+
+	for _, c := range cases {
+		got := matchGlob(c.dir, c.base, names)
+		if len(got) != len(c.want) {
+			t.Errorf("matchGlob(%q, %q, names) = %v, want %v", c.dir, c.base, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("matchGlob(%q, %q, names) = %v, want %v", c.dir, c.base, got, c.want)
+				break
+			}
+		}
+	}
+}
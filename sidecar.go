@@ -0,0 +1,112 @@
+package letterbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/buckket/go-blurhash"
+	"github.com/disintegration/imaging"
+)
+
+// sidecarData is the JSON document written alongside a processed image
+// when WithSidecar is enabled.
+type sidecarData struct {
+	BlurHash string `json:"blurhash"`
+	Dominant string `json:"dominant_color"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+// WithBlurhash sets the number of x and y components used when
+// encoding the BlurHash placeholder. Defaults to 4x3.
+func WithBlurhash(x, y int) Option {
+	return func(p *Processor) error {
+		p.blurhashX = x
+		p.blurhashY = y
+		return nil
+	}
+}
+
+// WithSidecar enables writing a "<dstpath>.json" sidecar containing a
+// BlurHash, dominant color, and final dimensions for each processed
+// image. Defaults to false.
+func WithSidecar(v bool) Option {
+	return func(p *Processor) error {
+		p.sidecar = v
+		return nil
+	}
+}
+
+// writeSidecar computes the BlurHash and dominant color of dst and
+// writes them, along with its dimensions, to dstpath + ".json".
+func (p *Processor) writeSidecar(dst image.Image, dstpath string) (err error) {
+	thumb := imaging.Resize(dst, 32, 32, p.resampleFilter)
+
+	hash, err := blurhash.Encode(p.blurhashX, p.blurhashY, thumb)
+	if err != nil {
+		return fmt.Errorf("encoding blurhash: %w", err)
+	}
+
+	b := dst.Bounds()
+	data := sidecarData{
+		BlurHash: hash,
+		Dominant: hexColor(dominantColorOf(thumb)),
+		Width:    b.Dx(),
+		Height:   b.Dy(),
+	}
+
+	w, err := p.dest.Create(dstpath + ".json")
+	if err != nil {
+		return fmt.Errorf("creating: %w", err)
+	}
+	defer func() {
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		return fmt.Errorf("encoding: %w", err)
+	}
+
+	return nil
+}
+
+// dominantColorOf returns the largest k-means cluster over img, used as
+// its dominant color.
+func dominantColorOf(img image.Image) color.Color {
+	const (
+		k          = 4
+		iterations = 8
+	)
+
+	samples := downsampleLinear(img, 32, 32)
+	centers, assignments := kmeans(samples, k, iterations)
+
+	counts := make([]int, k)
+	for _, a := range assignments {
+		counts[a]++
+	}
+
+	largest := 0
+	for c := 1; c < k; c++ {
+		if counts[c] > counts[largest] {
+			largest = c
+		}
+	}
+
+	return color.NRGBA{
+		R: linearToSRGB8(centers[largest][0]),
+		G: linearToSRGB8(centers[largest][1]),
+		B: linearToSRGB8(centers[largest][2]),
+		A: 0xff,
+	}
+}
+
+// hexColor formats c as a "#rrggbb" hex string.
+func hexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
@@ -0,0 +1,43 @@
+package letterbox
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestHexColor(t *testing.T) {
+	cases := []struct {
+		c    color.Color
+		want string
+	}{
+		{color.NRGBA{R: 0xff, G: 0x00, B: 0x00, A: 0xff}, "#ff0000"},
+		{color.NRGBA{R: 0x12, G: 0x34, B: 0x56, A: 0xff}, "#123456"},
+		{color.Black, "#000000"},
+		{color.White, "#ffffff"},
+	}
+
+	for _, c := range cases {
+		if got := hexColor(c.c); got != c.want {
+			t.Errorf("hexColor(%v) = %q, want %q", c.c, got, c.want)
+		}
+	}
+}
+
+func TestDominantColorOf(t *testing.T) {
+	// Solid red image: the dominant color should be red, regardless of
+	// the k-means cluster count.
+	img := image.NewUniform(color.NRGBA{R: 0xff, G: 0x00, B: 0x00, A: 0xff})
+	solid := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			solid.Set(x, y, img.At(x, y))
+		}
+	}
+
+	got := dominantColorOf(solid)
+	r, g, b, _ := got.RGBA()
+	if r>>8 < 0xf0 || g>>8 > 0x10 || b>>8 > 0x10 {
+		t.Errorf("dominantColorOf(solid red) = %v, want approximately red", got)
+	}
+}